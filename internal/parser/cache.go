@@ -0,0 +1,235 @@
+package parser
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+
+	messages "github.com/cucumber/messages/go/v24"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/cucumber/godog/internal/models"
+)
+
+// envParseMemLimit overrides the cache's default memory budget, expressed
+// in megabytes.
+const envParseMemLimit = "GODOG_PARSE_MEMLIMIT"
+
+// defaultMemLimitFraction is the divisor applied to runtime.MemStats.Sys
+// (one quarter) to get the default budget when envParseMemLimit is unset.
+const defaultMemLimitFraction = 4
+
+// Cache memoizes parsed features keyed by a hash of their path, dialect
+// and content, so that re-scanning an otherwise unchanged suite skips
+// gherkin parsing and pickle generation entirely. Entries are evicted in
+// least-recently-used order once the estimated in-memory size of the
+// cached features exceeds maxSize. A Cache is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	ll      *list.List
+	items   map[string]*list.Element
+	size    int64
+	maxSize int64
+
+	idMu   sync.Mutex
+	nextID uint64
+
+	group singleflight.Group
+}
+
+type cacheEntry struct {
+	key     string
+	feature *models.Feature
+	size    int64
+}
+
+// NewCache creates a Cache with the given memory budget in bytes. A
+// maxSize <= 0 falls back to GODOG_PARSE_MEMLIMIT (megabytes) when set, or
+// otherwise to one quarter of runtime.MemStats.Sys.
+func NewCache(maxSize int64) *Cache {
+	if maxSize <= 0 {
+		maxSize = defaultMemLimit()
+	}
+
+	return &Cache{
+		ll:      list.New(),
+		items:   make(map[string]*list.Element),
+		maxSize: maxSize,
+	}
+}
+
+func defaultMemLimit() int64 {
+	if v := os.Getenv(envParseMemLimit); v != "" {
+		if mb, err := strconv.ParseInt(v, 10, 64); err == nil && mb > 0 {
+			return mb * 1024 * 1024
+		}
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return int64(mem.Sys) / defaultMemLimitFraction
+}
+
+// ParseFeaturesWithCache behaves like ParseFeatures, except that each
+// resolved feature file is looked up in cache first and only parsed on a
+// miss. Concurrent misses for the same key are coalesced so only one
+// caller actually parses the file. A nil cache disables memoization
+// entirely.
+func ParseFeaturesWithCache(cache *Cache, fsys fs.FS, filter, dialect string, paths []string) ([]*models.Feature, error) {
+	if cache == nil {
+		return ParseFeatures(fsys, filter, dialect, paths)
+	}
+
+	return parseFeatures(fsys, filter, dialect, paths, cache.parseFeatureFile)
+}
+
+func (c *Cache) parseFeatureFile(fsys fs.FS, path, dialect string, _ func() string) (*models.Feature, error) {
+	content, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := cacheKey(path, dialect, content)
+
+	if ft, ok := c.get(key); ok {
+		return cloneFeature(ft), nil
+	}
+
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		if ft, ok := c.get(key); ok {
+			return ft, nil
+		}
+
+		// The ID namespace comes from the cache itself, not from the
+		// caller, so that an entry's IDs stay identical no matter which
+		// caller's newIDFunc would otherwise have populated the cache
+		// first, and unique across every parse this cache performs,
+		// including a re-parse of this key after it was evicted.
+		ft, err := parseBytes(path, content, dialect, c.idFuncFor(key))
+		if err != nil {
+			return nil, err
+		}
+
+		c.put(key, ft)
+		return ft, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Callers (filterByLine, filterFeatures) mutate the feature and its
+	// pickles in place, so every caller must get its own copy - otherwise
+	// one caller's filtering would corrupt what every other caller, now or
+	// later, reads back out of the cache.
+	return cloneFeature(v.(*models.Feature)), nil
+}
+
+// idFuncFor returns an ID generator dedicated to key, prefixed with a
+// counter that increments on every call - once per parse this Cache
+// performs, so a key parsed again after eviction gets a new prefix.
+// Handing out the prefix from a single in-process counter - rather than
+// deriving it from path or content - guarantees it is unique for the
+// lifetime of the Cache, with no hash-collision risk to reason about.
+func (c *Cache) idFuncFor(key string) func() string {
+	c.idMu.Lock()
+	n := c.nextID
+	c.nextID++
+	c.idMu.Unlock()
+
+	inc := &messages.Incrementing{}
+	prefix := strconv.FormatUint(n, 36) + "-"
+
+	return func() string {
+		return prefix + inc.NewId()
+	}
+}
+
+// cloneFeature copies ft and its pickles so the cache's own copy is never
+// mutated by a caller filtering its result.
+func cloneFeature(ft *models.Feature) *models.Feature {
+	doc := *ft.GherkinDocument
+
+	pickles := make([]*messages.Pickle, len(ft.Pickles))
+	for i, p := range ft.Pickles {
+		pc := *p
+		pickles[i] = &pc
+	}
+
+	return &models.Feature{
+		GherkinDocument: &doc,
+		Pickles:         pickles,
+		Content:         ft.Content,
+	}
+}
+
+func (c *Cache) get(key string) (*models.Feature, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	return el.Value.(*cacheEntry).feature, true
+}
+
+func (c *Cache) put(key string, ft *models.Feature) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.size -= el.Value.(*cacheEntry).size
+		c.ll.Remove(el)
+	}
+
+	entry := &cacheEntry{key: key, feature: ft, size: featureSize(ft)}
+	c.items[key] = c.ll.PushFront(entry)
+	c.size += entry.size
+
+	for c.size > c.maxSize && c.ll.Len() > 0 {
+		oldest := c.ll.Back()
+		if oldest == nil {
+			break
+		}
+
+		c.ll.Remove(oldest)
+		e := oldest.Value.(*cacheEntry)
+		delete(c.items, e.key)
+		c.size -= e.size
+	}
+}
+
+// cacheKey hashes path, dialect and content together so a cached entry is
+// only ever reused for the exact bytes it was parsed from.
+func cacheKey(path, dialect string, content []byte) string {
+	h := sha256.New()
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write([]byte(dialect))
+	h.Write([]byte{0})
+	h.Write(content)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// featureSize approximates the memory held by a parsed feature: its raw
+// content counted twice, to account for the gherkin AST built from it,
+// plus a flat per-pickle overhead for the pickles generated from it. This is intentionally a cheap estimate
+// rather than an exact accounting of the parsed structures.
+func featureSize(ft *models.Feature) int64 {
+	const astOverheadPerByte = 2
+	const pickleOverhead = 512
+
+	size := int64(len(ft.Content)) * astOverheadPerByte
+	size += int64(len(ft.Pickles)) * pickleOverhead
+
+	return size
+}
@@ -0,0 +1,212 @@
+package parser_test
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cucumber/godog/internal/models"
+	"github.com/cucumber/godog/internal/parser"
+)
+
+// cacheFeatureContent returns feature content for name, padded to a fixed
+// width so every name produces content of identical length - and so,
+// given the two entries have the same number of pickles, an identical
+// featureSize (see cache.go) - regardless of which name is used.
+func cacheFeatureContent(name string) string {
+	return fmt.Sprintf("Feature: %-8s\n  Scenario: one\n    Given a step\n", name)
+}
+
+func cacheTestFS(names ...string) fstest.MapFS {
+	fsys := make(fstest.MapFS, len(names))
+	for _, name := range names {
+		fsys[name+".feature"] = &fstest.MapFile{Data: []byte(cacheFeatureContent(name))}
+	}
+
+	return fsys
+}
+
+func firstPickleID(t *testing.T, feats []*models.Feature) string {
+	t.Helper()
+
+	require.Len(t, feats, 1)
+	require.NotEmpty(t, feats[0].Pickles)
+
+	return feats[0].Pickles[0].Id
+}
+
+func TestParseFeaturesWithCache_HitReusesPickleID(t *testing.T) {
+	fsys := cacheTestFS("a")
+	cache := parser.NewCache(1 << 20)
+
+	first, err := parser.ParseFeaturesWithCache(cache, fsys, "", "", []string{"a.feature"})
+	require.NoError(t, err)
+
+	second, err := parser.ParseFeaturesWithCache(cache, fsys, "", "", []string{"a.feature"})
+	require.NoError(t, err)
+
+	// A cache hit reuses the feature (and so the pickle IDs) generated by
+	// the first, cold parse rather than running gherkin again.
+	require.Equal(t, firstPickleID(t, first), firstPickleID(t, second))
+}
+
+func TestParseFeaturesWithCache_MissAfterEvictionReparses(t *testing.T) {
+	fsys := cacheTestFS("a")
+
+	// maxSize 1 can't hold even a single entry, so every lookup is a miss
+	// and must be reparsed from scratch with a fresh pickle ID.
+	cache := parser.NewCache(1)
+
+	first, err := parser.ParseFeaturesWithCache(cache, fsys, "", "", []string{"a.feature"})
+	require.NoError(t, err)
+
+	second, err := parser.ParseFeaturesWithCache(cache, fsys, "", "", []string{"a.feature"})
+	require.NoError(t, err)
+
+	require.NotEqual(t, firstPickleID(t, first), firstPickleID(t, second))
+}
+
+func TestParseFeaturesWithCache_RecencyProtectsTouchedEntry(t *testing.T) {
+	fsys := cacheTestFS("a", "b", "c")
+
+	// a.feature, b.feature and c.feature are built from equal-length
+	// content with one pickle each, so they share the same featureSize.
+	// Measure it the same way cache.go does (content length * 2, plus a
+	// flat 512-byte per-pickle overhead) and size the cache for exactly
+	// two entries, so inserting a third forces exactly one eviction.
+	entrySize := int64(len(cacheFeatureContent("a")))*2 + 512
+	cache := parser.NewCache(entrySize * 2)
+
+	a1, err := parser.ParseFeaturesWithCache(cache, fsys, "", "", []string{"a.feature"})
+	require.NoError(t, err)
+	b1, err := parser.ParseFeaturesWithCache(cache, fsys, "", "", []string{"b.feature"})
+	require.NoError(t, err)
+
+	// Touch a.feature again so it becomes the most recently used entry,
+	// making b.feature the one due for eviction next.
+	_, err = parser.ParseFeaturesWithCache(cache, fsys, "", "", []string{"a.feature"})
+	require.NoError(t, err)
+
+	_, err = parser.ParseFeaturesWithCache(cache, fsys, "", "", []string{"c.feature"})
+	require.NoError(t, err)
+
+	a2, err := parser.ParseFeaturesWithCache(cache, fsys, "", "", []string{"a.feature"})
+	require.NoError(t, err)
+	require.Equal(t, firstPickleID(t, a1), firstPickleID(t, a2), "a.feature was touched most recently and should still be cached")
+
+	b2, err := parser.ParseFeaturesWithCache(cache, fsys, "", "", []string{"b.feature"})
+	require.NoError(t, err)
+	require.NotEqual(t, firstPickleID(t, b1), firstPickleID(t, b2), "b.feature was the least recently used entry and should have been evicted")
+}
+
+// cacheTestFSLarge is like cacheTestFS, but pads each feature's raw
+// content (via comment lines, which the gherkin parser ignores) to about
+// 390KB, so each entry's estimated size (~780KB) is most of the
+// single-megabyte granularity GODOG_PARSE_MEMLIMIT is expressed in - any
+// two of these entries together exceed a 1MB budget, which a handful of
+// cacheTestFS's few-hundred-byte entries never would.
+func cacheTestFSLarge(names ...string) fstest.MapFS {
+	pad := strings.Repeat("# padding\n", 40000) // ~390KB
+
+	fsys := make(fstest.MapFS, len(names))
+	for _, name := range names {
+		data := pad + cacheFeatureContent(name)
+		fsys[name+".feature"] = &fstest.MapFile{Data: []byte(data)}
+	}
+
+	return fsys
+}
+
+func TestParseFeaturesWithCache_MemLimitEnvOverrideConstrainsBudget(t *testing.T) {
+	fsys := cacheTestFSLarge("a", "b", "c", "d", "e", "f")
+	paths := []string{"a.feature", "b.feature", "c.feature", "d.feature", "e.feature", "f.feature"}
+
+	orig := parser.ParseConcurrency
+	parser.ParseConcurrency = 1 // keep eviction order deterministic
+	defer func() { parser.ParseConcurrency = orig }()
+
+	t.Run("tight env budget evicts the whole working set", func(t *testing.T) {
+		t.Setenv("GODOG_PARSE_MEMLIMIT", "1")
+		cache := parser.NewCache(0)
+
+		first, err := parser.ParseFeaturesWithCache(cache, fsys, "", "", paths)
+		require.NoError(t, err)
+
+		second, err := parser.ParseFeaturesWithCache(cache, fsys, "", "", paths)
+		require.NoError(t, err)
+
+		for i := range paths {
+			require.NotEqual(t, first[i].Pickles[0].Id, second[i].Pickles[0].Id, "path %s should have been evicted under a 1MB budget", paths[i])
+		}
+	})
+
+	t.Run("generous budget keeps the whole working set cached", func(t *testing.T) {
+		cache := parser.NewCache(1 << 30)
+
+		first, err := parser.ParseFeaturesWithCache(cache, fsys, "", "", paths)
+		require.NoError(t, err)
+
+		second, err := parser.ParseFeaturesWithCache(cache, fsys, "", "", paths)
+		require.NoError(t, err)
+
+		for i := range paths {
+			require.Equal(t, first[i].Pickles[0].Id, second[i].Pickles[0].Id, "path %s should still have been cached", paths[i])
+		}
+	})
+}
+
+// releaseAfterFS wraps an fstest.MapFS and blocks every Open call until
+// want calls have arrived, then releases them all at once. That forces
+// every goroutine racing on the same cache miss to genuinely overlap,
+// rather than happening to interleave.
+type releaseAfterFS struct {
+	fstest.MapFS
+	want     int32
+	arrived  int32
+	released chan struct{}
+}
+
+func (r *releaseAfterFS) Open(name string) (fs.File, error) {
+	if atomic.AddInt32(&r.arrived, 1) == r.want {
+		close(r.released)
+	}
+	<-r.released
+
+	return r.MapFS.Open(name)
+}
+
+func TestParseFeaturesWithCache_CoalescesConcurrentMisses(t *testing.T) {
+	const callers = 8
+
+	fsys := &releaseAfterFS{MapFS: cacheTestFS("a"), want: callers, released: make(chan struct{})}
+	cache := parser.NewCache(1 << 20)
+
+	ids := make([]string, callers)
+	var wg sync.WaitGroup
+	wg.Add(callers)
+	for i := 0; i < callers; i++ {
+		i := i
+		go func() {
+			defer wg.Done()
+
+			feats, err := parser.ParseFeaturesWithCache(cache, fsys, "", "", []string{"a.feature"})
+			require.NoError(t, err)
+			ids[i] = firstPickleID(t, feats)
+		}()
+	}
+	wg.Wait()
+
+	// Every caller raced on the same key while the underlying file was
+	// only openable once all of them had arrived; singleflight must have
+	// coalesced them into a single parse, so every result carries the
+	// same pickle ID.
+	for i := 1; i < callers; i++ {
+		require.Equal(t, ids[0], ids[i])
+	}
+}
@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+
+	messages "github.com/cucumber/messages/go/v24"
+
+	"github.com/cucumber/godog/internal/models"
+)
+
+// ParseConcurrency bounds how many feature files ParseFeatures parses at
+// once. It defaults to GOMAXPROCS and can be lowered or raised before
+// parsing starts; values < 1 are treated as 1.
+var ParseConcurrency = runtime.GOMAXPROCS(0)
+
+// parsePathsConcurrently resolves every entry in paths to its constituent
+// feature files - preserving the same order ParseFeatures has always
+// produced - then parses them across a bounded pool of workers. Each file
+// gets its own Incrementing ID source, namespaced by its own path rather
+// than its position in the result slice, so that pickle and AST node IDs
+// stay stable and reproducible across runs regardless of how the workers
+// get scheduled. ParseFeaturesWithCache does not go through this
+// namespacing at all - Cache owns its own ID namespace per cached entry,
+// since a cache hit may serve a file's features at a different position
+// than the one it was originally parsed at.
+func parsePathsConcurrently(fsys fs.FS, dialect string, paths []string, parseFile parseFileFunc) ([]*models.Feature, error) {
+	var tasks []fileTask
+
+	for _, path := range paths {
+		// A trailing ":line" must come off before path reaches resolveSource,
+		// otherwise an http(s) URL would be fetched with the line suffix
+		// stuck to the end of it. It's only meaningful for a single-file
+		// target, so it's reattached below for everything but archives,
+		// which expand path into several files none of which it identifies.
+		basePath, line := ExtractFeaturePathLine(path)
+
+		srcFS, srcPaths, err := resolveSource(fsys, basePath).Load()
+
+		switch {
+		case os.IsNotExist(err):
+			return nil, fmt.Errorf(`feature path "%s" is not available`, path)
+		case os.IsPermission(err):
+			return nil, fmt.Errorf(`feature path "%s" is not accessible`, path)
+		case err != nil:
+			return nil, err
+		}
+
+		for _, sp := range srcPaths {
+			if line != -1 && !hasArchiveSuffix(basePath) {
+				sp += ":" + strconv.Itoa(line)
+			}
+
+			fileTasks, err := resolvePathTasks(srcFS, sp)
+
+			switch {
+			case os.IsNotExist(err):
+				return nil, fmt.Errorf(`feature path "%s" is not available`, path)
+			case os.IsPermission(err):
+				return nil, fmt.Errorf(`feature path "%s" is not accessible`, path)
+			case err != nil:
+				return nil, err
+			}
+
+			for _, ft := range fileTasks {
+				tasks = append(tasks, ft)
+			}
+		}
+	}
+
+	concurrency := ParseConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > len(tasks) {
+		concurrency = len(tasks)
+	}
+
+	features := make([]*models.Feature, len(tasks))
+	errs := make([]error, len(tasks))
+
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				task := tasks[i]
+				newIDFunc := namespacedIDFunc(task.path)
+
+				ft, err := parseFile(task.fsys, task.path, dialect, newIDFunc)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+
+				features[i] = filterByLine(ft, task.line)
+			}
+		}()
+	}
+
+	for i := range tasks {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return features, nil
+}
+
+// namespacedIDFunc returns an ID generator dedicated to a single feature
+// file. Prefixing each file's own Incrementing counter with its path -
+// rather than its position in the current result slice - keeps generated
+// IDs identical across runs and unique across distinct paths. Tasks that
+// resolve to the same path (a file named twice, or named with different
+// ":line" targets) get identical IDs.
+func namespacedIDFunc(path string) func() string {
+	inc := &messages.Incrementing{}
+	prefix := path + "-"
+
+	return func() string {
+		return prefix + inc.NewId()
+	}
+}
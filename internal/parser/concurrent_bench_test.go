@@ -0,0 +1,47 @@
+package parser_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"testing/fstest"
+
+	"github.com/cucumber/godog/internal/parser"
+)
+
+// generateCorpus builds an in-memory filesystem of n trivial feature files,
+// used to benchmark parsing throughput without touching disk.
+func generateCorpus(n int) fstest.MapFS {
+	fsys := make(fstest.MapFS, n)
+
+	for i := 0; i < n; i++ {
+		data := fmt.Sprintf("Feature: generated %d\n  Scenario: one\n    Given a step\n", i)
+		fsys[fmt.Sprintf("features/gen_%d.feature", i)] = &fstest.MapFile{Data: []byte(data)}
+	}
+
+	return fsys
+}
+
+func BenchmarkParseFeaturesSerial(b *testing.B) {
+	fsys := generateCorpus(2000)
+	parser.ParseConcurrency = 1
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.ParseFeatures(fsys, "", "", []string{"features"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseFeaturesParallel(b *testing.B) {
+	fsys := generateCorpus(2000)
+	parser.ParseConcurrency = runtime.GOMAXPROCS(0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.ParseFeatures(fsys, "", "", []string{"features"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package parser_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cucumber/godog/internal/parser"
+)
+
+func featureFSFromNames(names ...string) fstest.MapFS {
+	fsys := make(fstest.MapFS, len(names))
+
+	for _, name := range names {
+		data := fmt.Sprintf("Feature: %s\n  Scenario: one\n    Given a step\n", name)
+		fsys[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+
+	return fsys
+}
+
+func TestParseFeatures_PreservesInputOrder(t *testing.T) {
+	fsys := featureFSFromNames("b/2.feature", "b/1.feature", "a.feature")
+
+	orig := parser.ParseConcurrency
+	parser.ParseConcurrency = runtime.GOMAXPROCS(0)
+	defer func() { parser.ParseConcurrency = orig }()
+
+	feats, err := parser.ParseFeatures(fsys, "", "", []string{"a.feature", "b"})
+	require.NoError(t, err)
+	require.Len(t, feats, 3)
+
+	var uris []string
+	for _, f := range feats {
+		uris = append(uris, f.Uri)
+	}
+
+	// "a.feature" comes first because it's listed first in paths; "b"'s
+	// two files follow in the lexical order fs.WalkDir visits them in,
+	// regardless of how many workers raced to parse them.
+	require.Equal(t, []string{"a.feature", "b/1.feature", "b/2.feature"}, uris)
+}
+
+func TestParseFeatures_DedupsDuplicateURIs(t *testing.T) {
+	fsys := featureFSFromNames("features/a.feature", "features/b.feature")
+
+	feats, err := parser.ParseFeatures(fsys, "", "", []string{"features/a.feature", "features"})
+	require.NoError(t, err)
+
+	var uris []string
+	for _, f := range feats {
+		uris = append(uris, f.Uri)
+	}
+
+	// features/a.feature is named explicitly and then walked again as part
+	// of "features"; the second occurrence must be dropped, not the first.
+	require.Equal(t, []string{"features/a.feature", "features/b.feature"}, uris)
+}
+
+func TestParseFeatures_PropagatesParseErrors(t *testing.T) {
+	fsys := featureFSFromNames("good.feature")
+	fsys["bad.feature"] = &fstest.MapFile{Data: []byte("not a valid gherkin document {{{")}
+
+	_, err := parser.ParseFeatures(fsys, "", "", []string{"."})
+	require.Error(t, err)
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -70,34 +69,30 @@ func parseBytes(path string, feature []byte, dialect string, newIDFunc func() st
 	return &f, nil
 }
 
-func parseFeatureDir(fsys fs.FS, dir, dialect string, newIDFunc func() string) ([]*models.Feature, error) {
-	var features []*models.Feature
-	return features, fs.WalkDir(fsys, dir, func(p string, f fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if f.IsDir() {
-			return nil
-		}
-
-		if !strings.HasSuffix(p, ".feature") {
-			return nil
-		}
-
-		feat, err := parseFeatureFile(fsys, p, dialect, newIDFunc)
-		if err != nil {
-			return err
-		}
-
-		features = append(features, feat)
-		return nil
-	})
+// parseFileFunc parses a single feature file. It is the extension point
+// ParseFeaturesWithCache hooks into to serve already-parsed features
+// without re-running the gherkin parser.
+type parseFileFunc func(fsys fs.FS, path, dialect string, newIDFunc func() string) (*models.Feature, error)
+
+// fileTask is a single feature file resolved from one of the paths passed
+// to ParseFeatures, along with the fs.FS it should be opened from - the
+// caller-supplied fsys for on-disk paths, or an in-memory fs.FS for
+// entries materialized from an archive or a remote URL - and the line it
+// should be filtered to when the originating path was a "path:line"
+// target.
+type fileTask struct {
+	fsys fs.FS
+	path string
+	line int
 }
 
-func parsePath(fsys fs.FS, path, dialect string, newIDFunc func() string) ([]*models.Feature, error) {
-	var features []*models.Feature
-
+// resolvePathTasks expands a single paths[] entry - a feature file,
+// optionally suffixed with ":line", or a directory - into the ordered list
+// of concrete feature files it refers to. Directories are walked in the
+// same lexical order fs.WalkDir visits them in, so callers that flatten
+// resolvePathTasks results across multiple top-level paths preserve the
+// same ordering ParseFeatures has always produced.
+func resolvePathTasks(fsys fs.FS, path string) ([]fileTask, error) {
 	path, line := ExtractFeaturePathLine(path)
 
 	fi, err := func() (fs.FileInfo, error) {
@@ -110,74 +105,86 @@ func parsePath(fsys fs.FS, path, dialect string, newIDFunc func() string) ([]*mo
 		return file.Stat()
 	}()
 	if err != nil {
-		return features, err
+		return nil, err
 	}
 
-	if fi.IsDir() {
-		return parseFeatureDir(fsys, path, dialect, newIDFunc)
+	if !fi.IsDir() {
+		return []fileTask{{fsys: fsys, path: path, line: line}}, nil
 	}
 
-	ft, err := parseFeatureFile(fsys, path, dialect, newIDFunc)
-	if err != nil {
-		return features, err
-	}
+	var tasks []fileTask
+	err = fs.WalkDir(fsys, path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
 
-	// filter scenario by line number
-	var pickles []*messages.Pickle
+		if d.IsDir() {
+			return nil
+		}
+
+		if !strings.HasSuffix(p, ".feature") {
+			return nil
+		}
+
+		tasks = append(tasks, fileTask{fsys: fsys, path: p, line: -1})
+		return nil
+	})
+
+	return tasks, err
+}
 
-	if line != -1 {
-		ft.Uri += ":" + strconv.Itoa(line)
+// filterByLine narrows ft down to the scenario at line when line != -1,
+// suffixing the feature and pickle URIs with ":line" the same way a
+// "path:line" CLI argument has always been handled.
+func filterByLine(ft *models.Feature, line int) *models.Feature {
+	if line == -1 {
+		return ft
 	}
 
+	ft.Uri += ":" + strconv.Itoa(line)
+
+	var pickles []*messages.Pickle
 	for _, pickle := range ft.Pickles {
 		sc := ft.FindScenario(pickle.AstNodeIds[0])
 
-		if line == -1 || int64(line) == sc.Location.Line {
-			if line != -1 {
-				pickle.Uri += ":" + strconv.Itoa(line)
-			}
-
+		if int64(line) == sc.Location.Line {
+			pickle.Uri += ":" + strconv.Itoa(line)
 			pickles = append(pickles, pickle)
 		}
 	}
 	ft.Pickles = pickles
 
-	return append(features, ft), nil
+	return ft
 }
 
 // ParseFeatures ...
 func ParseFeatures(fsys fs.FS, filter, dialect string, paths []string) ([]*models.Feature, error) {
-	var order int
+	return parseFeatures(fsys, filter, dialect, paths, parseFeatureFile)
+}
 
+func parseFeatures(fsys fs.FS, filter, dialect string, paths []string, parseFile parseFileFunc) ([]*models.Feature, error) {
 	if dialect == "" {
 		dialect = gherkin.DefaultDialect
 	}
 
+	parsed, err := parsePathsConcurrently(fsys, dialect, paths, parseFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var order int
 	featureIdxs := make(map[string]int)
 	uniqueFeatureURI := make(map[string]*models.Feature)
-	newIDFunc := (&messages.Incrementing{}).NewId
-	for _, path := range paths {
-		feats, err := parsePath(fsys, path, dialect, newIDFunc)
-
-		switch {
-		case os.IsNotExist(err):
-			return nil, fmt.Errorf(`feature path "%s" is not available`, path)
-		case os.IsPermission(err):
-			return nil, fmt.Errorf(`feature path "%s" is not accessible`, path)
-		case err != nil:
-			return nil, err
-		}
 
-		for _, ft := range feats {
-			if _, duplicate := uniqueFeatureURI[ft.Uri]; duplicate {
-				continue
-			}
+	for _, ft := range parsed {
+		if _, duplicate := uniqueFeatureURI[ft.Uri]; duplicate {
+			continue
+		}
 
-			uniqueFeatureURI[ft.Uri] = ft
-			featureIdxs[ft.Uri] = order
+		uniqueFeatureURI[ft.Uri] = ft
+		featureIdxs[ft.Uri] = order
 
-			order++
-		}
+		order++
 	}
 
 	var features = make([]*models.Feature, len(uniqueFeatureURI))
@@ -0,0 +1,258 @@
+package parser
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// httpFetchTimeout bounds how long httpSource.Load waits for a remote
+// feature bundle, so an unreachable or stalled URL can't hang the whole
+// parse indefinitely.
+const httpFetchTimeout = 30 * time.Second
+
+var httpClient = &http.Client{Timeout: httpFetchTimeout}
+
+// archiveSuffixes lists the path/URL suffixes ParseFeatures recognizes as
+// archives of feature files rather than single feature files.
+var archiveSuffixes = []string{".tar.gz", ".tgz", ".tar", ".zip"}
+
+// Source resolves one paths[] entry passed to ParseFeatures into the fs.FS
+// and the paths within it to parse. The default behaviour - used for plain
+// on-disk paths - is to pass fsys and the path straight through unchanged;
+// archive and HTTP(S) sources instead fetch their content and materialize
+// it into an in-memory fs.FS so the rest of the pipeline (pickle
+// generation, the parse cache, the worker pool) never needs to know where
+// the bytes came from.
+type Source interface {
+	Load() (fs.FS, []string, error)
+}
+
+// resolveSource picks the Source implementation for a single paths[]
+// entry based on its prefix/suffix: http:// and https:// URLs are fetched
+// remotely, paths ending in a recognized archive suffix are opened as
+// archives, and everything else resolves straight through fsys.
+func resolveSource(fsys fs.FS, path string) Source {
+	switch {
+	case strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://"):
+		return httpSource{url: path}
+	case hasArchiveSuffix(path):
+		return archiveSource{fsys: fsys, path: path}
+	default:
+		return fsSource{fsys: fsys, path: path}
+	}
+}
+
+func hasArchiveSuffix(path string) bool {
+	for _, suf := range archiveSuffixes {
+		if strings.HasSuffix(path, suf) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fsSource is the plain filesystem Source used for every path that isn't
+// an archive or a remote URL.
+type fsSource struct {
+	fsys fs.FS
+	path string
+}
+
+func (s fsSource) Load() (fs.FS, []string, error) {
+	return s.fsys, []string{s.path}, nil
+}
+
+// archiveSource reads path from fsys and expands every *.feature entry it
+// contains into an in-memory fs.FS.
+type archiveSource struct {
+	fsys fs.FS
+	path string
+}
+
+func (s archiveSource) Load() (fs.FS, []string, error) {
+	content, err := fs.ReadFile(s.fsys, s.path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mem, paths, err := expandArchive(s.path, content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", s.path, err)
+	}
+
+	return mem, paths, nil
+}
+
+// httpSource fetches url and either parses it as a single feature file, or,
+// if it looks like an archive, expands it the same way archiveSource does.
+type httpSource struct {
+	url string
+}
+
+func (s httpSource) Load() (fs.FS, []string, error) {
+	resp, err := httpClient.Get(s.url)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("fetching %s: unexpected status %s", s.url, resp.Status)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if hasArchiveSuffix(s.url) {
+		mem, paths, err := expandArchive(s.url, content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", s.url, err)
+		}
+
+		return mem, paths, nil
+	}
+
+	return memFS{s.url: content}, []string{s.url}, nil
+}
+
+// expandArchive extracts every *.feature entry from an archive's raw
+// content, picking the format from name's suffix. Entries are namespaced
+// by source (see namespacedEntryPath) so that two archives - or an
+// archive and a URL - sharing an internal feature file name never
+// collide once their paths reach ParseFeatures' duplicate-URI dedup.
+func expandArchive(source string, content []byte) (memFS, []string, error) {
+	switch {
+	case strings.HasSuffix(source, ".zip"):
+		return expandZip(source, content)
+	case strings.HasSuffix(source, ".tar.gz") || strings.HasSuffix(source, ".tgz"):
+		gz, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, nil, err
+		}
+		defer gz.Close()
+
+		return expandTar(source, gz)
+	case strings.HasSuffix(source, ".tar"):
+		return expandTar(source, bytes.NewReader(content))
+	default:
+		return nil, nil, fmt.Errorf("unsupported archive format %q", source)
+	}
+}
+
+func expandTar(source string, r io.Reader) (memFS, []string, error) {
+	fsys := memFS{}
+	var paths []string
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if hdr.Typeflag != tar.TypeReg || !strings.HasSuffix(hdr.Name, ".feature") {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, tr); err != nil {
+			return nil, nil, err
+		}
+
+		path := namespacedEntryPath(source, hdr.Name)
+		fsys[path] = buf.Bytes()
+		paths = append(paths, path)
+	}
+
+	return fsys, paths, nil
+}
+
+func expandZip(source string, content []byte) (memFS, []string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fsys := memFS{}
+	var paths []string
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.HasSuffix(f.Name, ".feature") {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		path := namespacedEntryPath(source, f.Name)
+		fsys[path] = data
+		paths = append(paths, path)
+	}
+
+	return fsys, paths, nil
+}
+
+// namespacedEntryPath qualifies an archive/HTTP-sourced feature file's
+// in-source name with the archive path or URL it came from, so that two
+// sources sharing an internal name - two archives, or an archive and a
+// URL - never collide in ParseFeatures' duplicate-URI dedup map.
+func namespacedEntryPath(source, name string) string {
+	return source + "#" + name
+}
+
+// memFS is a minimal in-memory fs.FS backing the feature files extracted
+// from an archive or fetched over HTTP(S), so they can flow through the
+// same resolvePathTasks/parseFileFunc pipeline as files on disk.
+type memFS map[string][]byte
+
+func (m memFS) Open(name string) (fs.File, error) {
+	content, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return &memFile{Reader: bytes.NewReader(content), name: name, size: int64(len(content))}, nil
+}
+
+type memFile struct {
+	*bytes.Reader
+	name string
+	size int64
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return memFileInfo{name: f.name, size: f.size}, nil }
+func (f *memFile) Close() error               { return nil }
+
+type memFileInfo struct {
+	name string
+	size int64
+}
+
+func (fi memFileInfo) Name() string       { return fi.name }
+func (fi memFileInfo) Size() int64        { return fi.size }
+func (fi memFileInfo) Mode() fs.FileMode  { return 0 }
+func (fi memFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi memFileInfo) IsDir() bool        { return false }
+func (fi memFileInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,202 @@
+package parser_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cucumber/godog/internal/parser"
+)
+
+func buildTar(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(content))}
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+
+	return buf.Bytes()
+}
+
+func buildTarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write(buildTar(t, files))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	return buf.Bytes()
+}
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func TestParseFeatures_ExpandsArchives(t *testing.T) {
+	featureFiles := map[string]string{
+		"a.feature":        "Feature: a\n  Scenario: one\n    Given a step\n",
+		"nested/b.feature": "Feature: b\n  Scenario: one\n    Given a step\n",
+		"readme.txt":       "not a feature",
+	}
+
+	tests := []struct {
+		name    string
+		archive string
+		content []byte
+	}{
+		{"tar", "bundle.tar", buildTar(t, featureFiles)},
+		{"tar.gz", "bundle.tar.gz", buildTarGz(t, featureFiles)},
+		{"tgz", "bundle.tgz", buildTarGz(t, featureFiles)},
+		{"zip", "bundle.zip", buildZip(t, featureFiles)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := fstest.MapFS{tc.archive: &fstest.MapFile{Data: tc.content}}
+
+			feats, err := parser.ParseFeatures(fsys, "", "", []string{tc.archive})
+			require.NoError(t, err)
+			require.Len(t, feats, 2, "only the two *.feature entries should have been expanded")
+		})
+	}
+}
+
+func TestParseFeatures_EmptyArchiveYieldsNoFeatures(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive string
+		content []byte
+	}{
+		{"tar", "empty.tar", buildTar(t, nil)},
+		{"tar.gz", "empty.tar.gz", buildTarGz(t, nil)},
+		{"zip", "empty.zip", buildZip(t, nil)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := fstest.MapFS{tc.archive: &fstest.MapFile{Data: tc.content}}
+
+			feats, err := parser.ParseFeatures(fsys, "", "", []string{tc.archive})
+			require.NoError(t, err)
+			require.Empty(t, feats)
+		})
+	}
+}
+
+func TestParseFeatures_CorruptArchiveErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive string
+		content []byte
+	}{
+		{"tar", "bundle.tar", buildTar(t, map[string]string{"a.feature": "Feature: a\n"})[:10]},
+		{"tar.gz", "bundle.tar.gz", []byte("not actually gzip")},
+		{"zip", "bundle.zip", []byte("not actually a zip")},
+		{"unsupported suffix", "bundle.rar", []byte("anything")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := fstest.MapFS{tc.archive: &fstest.MapFile{Data: tc.content}}
+
+			_, err := parser.ParseFeatures(fsys, "", "", []string{tc.archive})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParseFeatures_HTTPSourceSingleFile(t *testing.T) {
+	const body = "Feature: remote\n  Scenario: one\n    Given a step\n"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	feats, err := parser.ParseFeatures(fstest.MapFS{}, "", "", []string{srv.URL + "/suite.feature"})
+	require.NoError(t, err)
+	require.Len(t, feats, 1)
+	require.Equal(t, body, string(feats[0].Content))
+}
+
+func TestParseFeatures_HTTPSourceArchive(t *testing.T) {
+	content := buildTarGz(t, map[string]string{"a.feature": "Feature: a\n  Scenario: one\n    Given a step\n"})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer srv.Close()
+
+	feats, err := parser.ParseFeatures(fstest.MapFS{}, "", "", []string{srv.URL + "/bundle.tar.gz"})
+	require.NoError(t, err)
+	require.Len(t, feats, 1)
+}
+
+func TestParseFeatures_HTTPSourceNon200Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	_, err := parser.ParseFeatures(fstest.MapFS{}, "", "", []string{srv.URL + "/missing.feature"})
+	require.Error(t, err)
+}
+
+// TestParseFeatures_NamespacesArchiveAndURLEntriesBySource guards against
+// two distinct sources - two archives, or an archive and a URL - that
+// happen to contain a feature file with the same internal name silently
+// colliding in ParseFeatures' duplicate-URI dedup and dropping one of
+// them.
+func TestParseFeatures_NamespacesArchiveAndURLEntriesBySource(t *testing.T) {
+	contentA := buildTar(t, map[string]string{"login.feature": "Feature: from a\n  Scenario: one\n    Given a step\n"})
+	contentB := buildTar(t, map[string]string{"login.feature": "Feature: from b\n  Scenario: one\n    Given a step\n"})
+
+	fsys := fstest.MapFS{
+		"a.tar": &fstest.MapFile{Data: contentA},
+		"b.tar": &fstest.MapFile{Data: contentB},
+	}
+
+	feats, err := parser.ParseFeatures(fsys, "", "", []string{"a.tar", "b.tar"})
+	require.NoError(t, err)
+	require.Len(t, feats, 2, "archives sharing an internal filename must not collide in the dedup map")
+
+	var contents []string
+	for _, f := range feats {
+		contents = append(contents, string(f.Content))
+	}
+	require.ElementsMatch(t, []string{
+		"Feature: from a\n  Scenario: one\n    Given a step\n",
+		"Feature: from b\n  Scenario: one\n    Given a step\n",
+	}, contents)
+}
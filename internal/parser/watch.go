@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/cucumber/godog/internal/models"
+)
+
+// defaultWatchDebounce coalesces bursts of filesystem events - for example
+// an editor writing a file in several steps - into a single re-parse.
+const defaultWatchDebounce = 100 * time.Millisecond
+
+// Watcher re-parses the feature paths it was started with whenever the
+// underlying files change. Create one with WatchFeatures and call Stop
+// once it is no longer needed.
+type Watcher struct {
+	fsw    *fsnotify.Watcher
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// WatchFeatures resolves paths against fsys - each a feature file,
+// optionally suffixed with :line, or a directory - then watches the
+// resolved feature directories for create, write, remove and rename
+// events. Unlike ParseFeatures, it does not resolve http(s) URLs (they
+// fail with an error), and only events on *.feature files trigger a
+// re-parse, so changes to an archive path are not picked up. Events arriving within debounce of one another are
+// coalesced into a single re-parse, and the resulting feature set - or the
+// error encountered while producing it - is delivered to onChange. A
+// debounce <= 0 uses defaultWatchDebounce.
+//
+// Because fsnotify only watches real filesystem paths, fsys must be an
+// os.DirFS (or equivalent) rooted at root - the real on-disk directory
+// every path resolved through fsys is relative to. fsys alone doesn't
+// expose that root, so root must name it explicitly rather than leaving
+// it to be guessed from the process' working directory, which is only
+// ever correct by coincidence.
+//
+// The returned Watcher must be stopped with Stop to release its resources.
+func WatchFeatures(ctx context.Context, fsys fs.FS, root, filter, dialect string, paths []string, debounce time.Duration, onChange func([]*models.Feature, error)) (*Watcher, error) {
+	if root == "" {
+		return nil, errors.New("parser: WatchFeatures requires a non-empty root naming the real directory fsys is rooted at")
+	}
+
+	if debounce <= 0 {
+		debounce = defaultWatchDebounce
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	dirs, err := watchDirs(fsys, root, paths)
+	if err != nil {
+		fsw.Close()
+		return nil, err
+	}
+
+	for _, dir := range dirs {
+		if err := fsw.Add(dir); err != nil {
+			fsw.Close()
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &Watcher{fsw: fsw, cancel: cancel}
+
+	w.wg.Add(1)
+	go w.loop(ctx, fsys, filter, dialect, paths, debounce, onChange)
+
+	return w, nil
+}
+
+// Stop tears down the underlying filesystem watches and waits for the
+// watch goroutine to exit.
+func (w *Watcher) Stop() error {
+	w.cancel()
+	w.wg.Wait()
+	return w.fsw.Close()
+}
+
+func (w *Watcher) loop(ctx context.Context, fsys fs.FS, filter, dialect string, paths []string, debounce time.Duration, onChange func([]*models.Feature, error)) {
+	defer w.wg.Done()
+
+	var timer *time.Timer
+	var timerC <-chan time.Time
+
+	scheduleReparse := func() {
+		if timer == nil {
+			timer = time.NewTimer(debounce)
+			timerC = timer.C
+			return
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(debounce)
+		timerC = timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			onChange(nil, err)
+
+		case ev, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if !strings.HasSuffix(ev.Name, ".feature") {
+				continue
+			}
+			scheduleReparse()
+
+		case <-timerC:
+			timerC = nil
+			feats, err := ParseFeatures(fsys, filter, dialect, paths)
+			onChange(feats, err)
+		}
+	}
+}
+
+// watchDirs expands paths - each of which may point at a single feature
+// file (optionally suffixed with :line) or a directory - into the set of
+// directories fsnotify should watch, as real paths rooted at root.
+func watchDirs(fsys fs.FS, root string, paths []string) ([]string, error) {
+	seen := make(map[string]struct{})
+	var dirs []string
+
+	add := func(dir string) {
+		if _, ok := seen[dir]; ok {
+			return
+		}
+		seen[dir] = struct{}{}
+		dirs = append(dirs, dir)
+	}
+
+	for _, p := range paths {
+		p, _ = ExtractFeaturePathLine(p)
+
+		fi, err := fs.Stat(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+
+		if !fi.IsDir() {
+			add(toWatchPath(root, filepath.Dir(p)))
+			continue
+		}
+
+		err = fs.WalkDir(fsys, p, func(sub string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				add(toWatchPath(root, sub))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return dirs, nil
+}
+
+// toWatchPath joins an fs.FS-relative directory onto root, the real
+// on-disk directory fsys is rooted at, producing the real path fsnotify
+// needs to watch.
+func toWatchPath(root, dir string) string {
+	return filepath.Join(root, dir)
+}
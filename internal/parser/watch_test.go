@@ -0,0 +1,146 @@
+package parser_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cucumber/godog/internal/models"
+	"github.com/cucumber/godog/internal/parser"
+)
+
+const watchTestTimeout = 2 * time.Second
+
+func waitForChange(t *testing.T, changes chan []*models.Feature, errs chan error) []*models.Feature {
+	t.Helper()
+
+	select {
+	case feats := <-changes:
+		return feats
+	case err := <-errs:
+		t.Fatalf("onChange delivered an error: %v", err)
+		return nil
+	case <-time.After(watchTestTimeout):
+		t.Fatal("timed out waiting for onChange")
+		return nil
+	}
+}
+
+func requireNoChange(t *testing.T, changes chan []*models.Feature, d time.Duration) {
+	t.Helper()
+
+	select {
+	case feats := <-changes:
+		t.Fatalf("unexpected onChange after Stop: %v", feats)
+	case <-time.After(d):
+	}
+}
+
+func TestWatchFeatures_RequiresRoot(t *testing.T) {
+	fsys := os.DirFS(t.TempDir())
+
+	_, err := parser.WatchFeatures(context.Background(), fsys, "", "", "", nil, 0, nil)
+	require.Error(t, err)
+}
+
+func TestWatchFeatures_DetectsChangeUnderExplicitRoot(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "features"), 0o755))
+
+	featurePath := filepath.Join(root, "features", "a.feature")
+	require.NoError(t, os.WriteFile(featurePath, []byte("Feature: v1\n  Scenario: one\n    Given a step\n"), 0o644))
+
+	fsys := os.DirFS(root)
+
+	changes := make(chan []*models.Feature, 10)
+	errs := make(chan error, 10)
+	onChange := func(feats []*models.Feature, err error) {
+		if err != nil {
+			errs <- err
+			return
+		}
+		changes <- feats
+	}
+
+	// root here is an explicit tmp directory that is never the test
+	// binary's working directory, so this only passes if WatchFeatures
+	// actually watches root - not whatever os.Getwd() happens to return.
+	w, err := parser.WatchFeatures(context.Background(), fsys, root, "", "", []string{"features"}, 20*time.Millisecond, onChange)
+	require.NoError(t, err)
+	defer w.Stop()
+
+	require.NoError(t, os.WriteFile(featurePath, []byte("Feature: v2\n  Scenario: one\n    Given a step\n"), 0o644))
+
+	feats := waitForChange(t, changes, errs)
+	require.Len(t, feats, 1)
+	require.Equal(t, "Feature: v2\n  Scenario: one\n    Given a step\n", string(feats[0].Content))
+}
+
+func TestWatchFeatures_DebouncesBurstsIntoOneReparse(t *testing.T) {
+	root := t.TempDir()
+	featurePath := filepath.Join(root, "a.feature")
+	require.NoError(t, os.WriteFile(featurePath, []byte("Feature: v1\n  Scenario: one\n    Given a step\n"), 0o644))
+
+	fsys := os.DirFS(root)
+
+	changes := make(chan []*models.Feature, 10)
+	errs := make(chan error, 10)
+	onChange := func(feats []*models.Feature, err error) {
+		if err != nil {
+			errs <- err
+			return
+		}
+		changes <- feats
+	}
+
+	const debounce = 100 * time.Millisecond
+
+	w, err := parser.WatchFeatures(context.Background(), fsys, root, "", "", []string{"a.feature"}, debounce, onChange)
+	require.NoError(t, err)
+	defer w.Stop()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, os.WriteFile(featurePath, []byte("Feature: v2\n  Scenario: one\n    Given a step\n"), 0o644))
+		time.Sleep(debounce / 4)
+	}
+
+	waitForChange(t, changes, errs)
+
+	// Give any further (wrongly uncoalesced) reparse time to arrive before
+	// declaring the burst collapsed into a single onChange call.
+	select {
+	case feats := <-changes:
+		t.Fatalf("expected the burst of writes to coalesce into one onChange, got a second: %v", feats)
+	case <-time.After(debounce * 2):
+	}
+}
+
+func TestWatchFeatures_StopEndsTheWatchGoroutine(t *testing.T) {
+	root := t.TempDir()
+	featurePath := filepath.Join(root, "a.feature")
+	require.NoError(t, os.WriteFile(featurePath, []byte("Feature: v1\n  Scenario: one\n    Given a step\n"), 0o644))
+
+	fsys := os.DirFS(root)
+
+	changes := make(chan []*models.Feature, 10)
+	errs := make(chan error, 10)
+	onChange := func(feats []*models.Feature, err error) {
+		if err != nil {
+			errs <- err
+			return
+		}
+		changes <- feats
+	}
+
+	w, err := parser.WatchFeatures(context.Background(), fsys, root, "", "", []string{"a.feature"}, 20*time.Millisecond, onChange)
+	require.NoError(t, err)
+
+	require.NoError(t, w.Stop())
+
+	require.NoError(t, os.WriteFile(featurePath, []byte("Feature: v2\n  Scenario: one\n    Given a step\n"), 0o644))
+	requireNoChange(t, changes, 200*time.Millisecond)
+}